@@ -2,10 +2,15 @@ package collector
 
 import (
 	"encoding/json"
+	"fmt"
 	"log/slog"
+	"path"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/alecthomas/kingpin/v2"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -13,17 +18,68 @@ const (
 	vrrpStatusInitialize = "Initialize"
 	vrrpStatusBackup     = "Backup"
 	vrrpStatusMaster     = "Master"
+
+	// vrrpMissedAdvertMultiplier mirrors the keepalived/VRRP convention that a
+	// peer is considered to have missed an advertisement window after three
+	// advertisement intervals plus skew time elapse without one being received.
+	vrrpMissedAdvertMultiplier = 3
 )
 
 var (
-	vrrpSubsystem = "vrrp"
-	vrrpStates    = []string{vrrpStatusInitialize, vrrpStatusMaster, vrrpStatusBackup}
+	vrrpSubsystem         = "vrrp"
+	vrrpStates            = []string{vrrpStatusInitialize, vrrpStatusMaster, vrrpStatusBackup}
+	vrrpLegacyStateMetric = kingpin.Flag("collector.vrrp.legacy-state-metric", "Emit the deprecated one-hot frr_vrrp_state gauge instead of frr_vrrp_state/frr_vrrp_state_info.").Default("false").Bool()
+	vrrpVridsFilter       = kingpin.Flag("collector.vrrp.vrids", "Comma-separated list of VRIDs to collect, wildcards ('*') supported.").Default("*").String()
+	vrrpInterfacesFilter  = kingpin.Flag("collector.vrrp.interfaces", "Comma-separated list of interfaces to collect, wildcards ('*') supported.").Default("*").String()
+	vrrpAddressFamily     = kingpin.Flag("collector.vrrp.address-family", "Restrict VRRP collection to an address family.").Default("both").Enum("v4", "v6", "both")
 )
 
 func init() {
 	registerCollector(vrrpSubsystem, disabledByDefault, NewVRRPCollector)
 }
 
+// vrrpStateCode returns the numeric code of a VRRP state, matching its index in vrrpStates.
+func vrrpStateCode(status string) int {
+	for i, state := range vrrpStates {
+		if strings.EqualFold(status, state) {
+			return i
+		}
+	}
+	return -1
+}
+
+// vrrpCentisecondsToSeconds converts an FRR VRRP timer value, reported in
+// centiseconds, to seconds.
+func vrrpCentisecondsToSeconds(cs float64) float64 {
+	return cs / 100
+}
+
+// vrrpParseFilter splits a comma-separated --collector.vrrp.vrids/interfaces
+// flag value into its individual allowlist entries.
+func vrrpParseFilter(filter string) []string {
+	var entries []string
+	for _, entry := range strings.Split(filter, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// vrrpMatchesFilter reports whether value matches any of the shell-style
+// wildcard patterns in filters. An empty filter list allows everything.
+func vrrpMatchesFilter(value string, filters []string) bool {
+	if len(filters) == 0 {
+		return true
+	}
+	for _, filter := range filters {
+		if ok, err := path.Match(filter, value); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
+
 type VrrpVrInfo struct {
 	Vrid      uint32
 	Interface string
@@ -32,9 +88,23 @@ type VrrpVrInfo struct {
 }
 
 type VrrpInstanceInfo struct {
-	Subinterface string `json:"interface"`
-	Status       string
-	Statistics   VrrpInstanceStats `json:"stats"`
+	Subinterface      string `json:"interface"`
+	Status            string
+	Priority          *uint8 `json:"priority"`
+	EffectivePriority *uint8 `json:"effectivePriority"`
+	Preempt           *bool  `json:"preempt"`
+	AcceptMode        *bool  `json:"acceptMode"`
+	// AdvertisementInterval, MasterAdverInterval, SkewTime and
+	// MasterDownInterval are reported by FRR in centiseconds; convert with
+	// vrrpCentisecondsToSeconds before use.
+	AdvertisementInterval *float64          `json:"advertisementInterval"`
+	MasterAdverInterval   *float64          `json:"masterAdverInterval"`
+	SkewTime              *float64          `json:"skewTime"`
+	MasterDownInterval    *float64          `json:"masterDownInterval"`
+	AddressOwner          *bool             `json:"addressOwner"`
+	Mac                   string            `json:"mac"`
+	Addresses             []string          `json:"addresses"`
+	Statistics            VrrpInstanceStats `json:"stats"`
 }
 
 type VrrpInstanceStats struct {
@@ -45,70 +115,276 @@ type VrrpInstanceStats struct {
 	Transitions     *uint32
 }
 
+// vrrpAdvertTracker keeps the per-scrape state needed to derive advertisement
+// jitter and missed advertisements for a single (vrid, proto, interface) triple.
+type vrrpAdvertTracker struct {
+	haveLastAdverRx bool
+	lastAdverRx     uint32
+	lastScrape      time.Time
+	lastChange      time.Time
+	histogram       prometheus.Histogram
+	missedTotal     prometheus.Counter
+}
+
 type vrrpCollector struct {
-	logger       *slog.Logger
-	descriptions map[string]*prometheus.Desc
+	logger            *slog.Logger
+	descriptions      map[string]*prometheus.Desc
+	legacyStateMetric bool
+	vridFilters       []string
+	interfaceFilters  []string
+	addressFamily     string
+
+	advertMu       sync.Mutex
+	advertTrackers map[string]*vrrpAdvertTracker
 }
 
 // NewVRRPCollector collects VRRP metrics, implemented as per the Collector interface.
 func NewVRRPCollector(logger *slog.Logger) (Collector, error) {
-	return &vrrpCollector{logger: logger, descriptions: getVRRPDesc()}, nil
+	return &vrrpCollector{
+		logger:            logger,
+		descriptions:      getVRRPDesc(),
+		legacyStateMetric: *vrrpLegacyStateMetric,
+		vridFilters:       vrrpParseFilter(*vrrpVridsFilter),
+		interfaceFilters:  vrrpParseFilter(*vrrpInterfacesFilter),
+		addressFamily:     *vrrpAddressFamily,
+		advertTrackers:    make(map[string]*vrrpAdvertTracker),
+	}, nil
+}
+
+// singleVRID returns the VRID to collect when --collector.vrrp.vrids names
+// exactly one non-wildcard VRID, so Update can query it directly instead of
+// pulling every VRID from vtysh.
+func (c *vrrpCollector) singleVRID() (uint32, bool) {
+	if len(c.vridFilters) != 1 {
+		return 0, false
+	}
+	vrid, err := strconv.ParseUint(c.vridFilters[0], 10, 32)
+	if err != nil {
+		return 0, false
+	}
+	return uint32(vrid), true
+}
+
+// vrrpAdvertTrackerKey uniquely identifies a VRRP instance for advertisement tracking.
+func vrrpAdvertTrackerKey(proto string, vrid uint32, iface string) string {
+	return fmt.Sprintf("%s/%d/%s", proto, vrid, iface)
+}
+
+// trackAdvertisements derives the advertisement inter-arrival time from
+// delta(AdverRx) over the elapsed time since the previous scrape, and counts
+// missed advertisements while in Backup state, emitting the resulting
+// histogram and counter onto ch.
+func (c *vrrpCollector) trackAdvertisements(ch chan<- prometheus.Metric, proto string, vrid uint32, iface string, instance VrrpInstanceInfo) {
+	if instance.Statistics.AdverRx == nil || instance.AdvertisementInterval == nil {
+		return
+	}
+
+	key := vrrpAdvertTrackerKey(proto, vrid, iface)
+	vridLabel := strconv.FormatUint(uint64(vrid), 10)
+
+	c.advertMu.Lock()
+	defer c.advertMu.Unlock()
+
+	tracker, ok := c.advertTrackers[key]
+	if !ok {
+		constLabels := prometheus.Labels{"proto": proto, "vrid": vridLabel, "interface": iface}
+		tracker = &vrrpAdvertTracker{
+			histogram: prometheus.NewHistogram(prometheus.HistogramOpts{
+				Namespace:                   "frr",
+				Subsystem:                   vrrpSubsystem,
+				Name:                        "advertisement_interarrival_seconds",
+				Help:                        "Observed inter-arrival time between received VRRP advertisements.",
+				ConstLabels:                 constLabels,
+				NativeHistogramBucketFactor: 1.1,
+			}),
+			missedTotal: prometheus.NewCounter(prometheus.CounterOpts{
+				Namespace:   "frr",
+				Subsystem:   vrrpSubsystem,
+				Name:        "advertisements_missed_total",
+				Help:        "Number of times a VRRP advertisement was not received in time while in Backup state.",
+				ConstLabels: constLabels,
+			}),
+		}
+		c.advertTrackers[key] = tracker
+	}
+
+	now := time.Now()
+	adverRx := *instance.Statistics.AdverRx
+
+	if tracker.haveLastAdverRx {
+		elapsed := now.Sub(tracker.lastScrape).Seconds()
+
+		switch {
+		case adverRx > tracker.lastAdverRx:
+			// At least one advertisement arrived; the average inter-arrival
+			// time over the scrape window is elapsed time divided by the
+			// number of adverts received since the last scrape.
+			if elapsed > 0 {
+				tracker.histogram.Observe(elapsed / float64(adverRx-tracker.lastAdverRx))
+			}
+			tracker.lastChange = now
+		case adverRx < tracker.lastAdverRx:
+			// AdverRx was reset (e.g. peer restart); treat it as activity
+			// since we can't derive a meaningful interval across the reset.
+			tracker.lastChange = now
+		default:
+			if strings.EqualFold(instance.Status, vrrpStatusBackup) && !tracker.lastChange.IsZero() {
+				threshold := vrrpMissedAdvertMultiplier * vrrpCentisecondsToSeconds(*instance.AdvertisementInterval)
+				if instance.SkewTime != nil {
+					threshold += vrrpCentisecondsToSeconds(*instance.SkewTime)
+				}
+
+				thresholdDur := time.Duration(threshold * float64(time.Second))
+				// Latch the stall: count one missed advert per elapsed
+				// threshold window, advancing lastChange so the same
+				// outage isn't recounted on every subsequent scrape.
+				for thresholdDur > 0 && now.Sub(tracker.lastChange) >= thresholdDur {
+					tracker.missedTotal.Inc()
+					tracker.lastChange = tracker.lastChange.Add(thresholdDur)
+				}
+			}
+		}
+	} else {
+		tracker.lastChange = now
+	}
+
+	tracker.lastAdverRx = adverRx
+	tracker.haveLastAdverRx = true
+	tracker.lastScrape = now
+
+	ch <- tracker.histogram
+	ch <- tracker.missedTotal
+}
+
+// pruneAdvertTrackers removes tracked state for VRRP instances that no longer
+// appear in the scraped output.
+func (c *vrrpCollector) pruneAdvertTrackers(present map[string]struct{}) {
+	c.advertMu.Lock()
+	defer c.advertMu.Unlock()
+
+	for key := range c.advertTrackers {
+		if _, ok := present[key]; !ok {
+			delete(c.advertTrackers, key)
+		}
+	}
 }
 
 func getVRRPDesc() map[string]*prometheus.Desc {
 	labels := []string{"proto", "vrid", "interface", "subinterface"}
 	stateLabels := append(labels, "state")
 
+	infoLabels := append(labels, "mac", "addresses")
+
 	return map[string]*prometheus.Desc{
-		"vrrpState":       colPromDesc(vrrpSubsystem, "state", "Status of the VRRP state machine.", stateLabels),
-		"adverTx":         colPromDesc(vrrpSubsystem, "advertisements_sent_total", "Advertisements sent total.", labels),
-		"adverRx":         colPromDesc(vrrpSubsystem, "advertisements_received_total", "Advertisements received total.", labels),
-		"garpTx":          colPromDesc(vrrpSubsystem, "gratuitous_arp_sent_total", "Gratuitous ARP sent total.", labels),
-		"neighborAdverTx": colPromDesc(vrrpSubsystem, "neighbor_advertisements_sent_total", "Neighbor Advertisements sent total.", labels),
-		"transitions":     colPromDesc(vrrpSubsystem, "state_transitions_total", "Number of transitions of the VRRP state machine in total.", labels),
+		"vrrpState":             colPromDesc(vrrpSubsystem, "state", "Status of the VRRP state machine (deprecated, use frr_vrrp_state and frr_vrrp_state_info).", stateLabels),
+		"vrrpStateCode":         colPromDesc(vrrpSubsystem, "state", "Numeric code of the current VRRP state machine state (0=Initialize, 1=Master, 2=Backup).", labels),
+		"vrrpStateInfo":         colPromDesc(vrrpSubsystem, "state_info", "OpenMetrics StateSet of the VRRP state machine, 1 for the active state.", stateLabels),
+		"adverTx":               colPromDesc(vrrpSubsystem, "advertisements_sent_total", "Advertisements sent total.", labels),
+		"adverRx":               colPromDesc(vrrpSubsystem, "advertisements_received_total", "Advertisements received total.", labels),
+		"garpTx":                colPromDesc(vrrpSubsystem, "gratuitous_arp_sent_total", "Gratuitous ARP sent total.", labels),
+		"neighborAdverTx":       colPromDesc(vrrpSubsystem, "neighbor_advertisements_sent_total", "Neighbor Advertisements sent total.", labels),
+		"transitions":           colPromDesc(vrrpSubsystem, "state_transitions_total", "Number of transitions of the VRRP state machine in total.", labels),
+		"priority":              colPromDesc(vrrpSubsystem, "priority", "Configured priority of the VRRP instance.", labels),
+		"effectivePriority":     colPromDesc(vrrpSubsystem, "effective_priority", "Effective priority of the VRRP instance.", labels),
+		"advertisementInterval": colPromDesc(vrrpSubsystem, "advertisement_interval_seconds", "Configured advertisement interval.", labels),
+		"masterAdverInterval":   colPromDesc(vrrpSubsystem, "master_advertisement_interval_seconds", "Advertisement interval being used by the current master.", labels),
+		"skewTime":              colPromDesc(vrrpSubsystem, "skew_time_seconds", "Skew time derived from the effective priority.", labels),
+		"masterDownInterval":    colPromDesc(vrrpSubsystem, "master_down_interval_seconds", "Time without receiving advertisements before declaring the master down.", labels),
+		"preempt":               colPromDesc(vrrpSubsystem, "preempt_enabled", "Whether preemption is enabled for the VRRP instance.", labels),
+		"acceptMode":            colPromDesc(vrrpSubsystem, "accept_mode_enabled", "Whether accept mode is enabled for the VRRP instance.", labels),
+		"addressOwner":          colPromDesc(vrrpSubsystem, "address_owner", "Whether this VRRP instance owns the virtual IP addresses.", labels),
+		"instanceInfo":          colPromDesc(vrrpSubsystem, "instance_info", "Non-numeric data about the VRRP instance, value is always 1.", infoLabels),
 	}
 }
 
+// vrrpAllowed reports whether a VRID/interface pair passes the configured
+// --collector.vrrp.vrids/--collector.vrrp.interfaces filters.
+func (c *vrrpCollector) vrrpAllowed(vrid uint32, iface string) bool {
+	return vrrpMatchesFilter(strconv.FormatUint(uint64(vrid), 10), c.vridFilters) &&
+		vrrpMatchesFilter(iface, c.interfaceFilters)
+}
+
 // Update implemented as per the Collector interface.
 func (c *vrrpCollector) Update(ch chan<- prometheus.Metric) error {
 	cmd := "show vrrp json"
+	if vrid, ok := c.singleVRID(); ok {
+		cmd = fmt.Sprintf("show vrrp vrid %d json", vrid)
+	}
 	jsonVRRPInfo, err := executeVRRPCommand(cmd)
 	if err != nil {
 		return err
 	}
-	if err := processVRRPInfo(ch, jsonVRRPInfo, c.descriptions); err != nil {
+
+	if err := c.processVRRPInfo(ch, jsonVRRPInfo); err != nil {
 		return cmdOutputProcessError(cmd, string(jsonVRRPInfo), err)
 	}
 	return nil
 }
 
-func processVRRPInfo(ch chan<- prometheus.Metric, jsonVRRPInfo []byte, desc map[string]*prometheus.Desc) error {
+// processVRRPInfo takes the vtysh output as jsonVRRPInfo rather than running
+// the command itself, so it can be exercised against fixtures without
+// shelling out to vtysh. It is not pure: it updates and prunes the
+// collector's advertisement trackers, which persist between calls.
+func (c *vrrpCollector) processVRRPInfo(ch chan<- prometheus.Metric, jsonVRRPInfo []byte) error {
 	var jsonList []VrrpVrInfo
 	if err := json.Unmarshal(jsonVRRPInfo, &jsonList); err != nil {
 		return err
 	}
 
+	present := make(map[string]struct{}, len(jsonList)*2)
+
 	for _, vrInfo := range jsonList {
-		processInstance(ch, "v4", vrInfo.Vrid, vrInfo.Interface, vrInfo.V4Info, desc)
-		processInstance(ch, "v6", vrInfo.Vrid, vrInfo.Interface, vrInfo.V6Info, desc)
+		if !c.vrrpAllowed(vrInfo.Vrid, vrInfo.Interface) {
+			continue
+		}
+
+		v4, v6 := vrInfo.V4Info, vrInfo.V6Info
+
+		if c.addressFamily == "v4" || c.addressFamily == "both" {
+			processInstance(ch, "v4", vrInfo.Vrid, vrInfo.Interface, v4, c.descriptions, c.legacyStateMetric)
+			c.trackAdvertisements(ch, "v4", vrInfo.Vrid, vrInfo.Interface, v4)
+			present[vrrpAdvertTrackerKey("v4", vrInfo.Vrid, vrInfo.Interface)] = struct{}{}
+		}
+
+		if c.addressFamily == "v6" || c.addressFamily == "both" {
+			processInstance(ch, "v6", vrInfo.Vrid, vrInfo.Interface, v6, c.descriptions, c.legacyStateMetric)
+			c.trackAdvertisements(ch, "v6", vrInfo.Vrid, vrInfo.Interface, v6)
+			present[vrrpAdvertTrackerKey("v6", vrInfo.Vrid, vrInfo.Interface)] = struct{}{}
+		}
 	}
 
+	c.pruneAdvertTrackers(present)
+
 	return nil
 }
 
-func processInstance(ch chan<- prometheus.Metric, proto string, vrid uint32, iface string, instance VrrpInstanceInfo, vrrpDesc map[string]*prometheus.Desc) {
+func processInstance(ch chan<- prometheus.Metric, proto string, vrid uint32, iface string, instance VrrpInstanceInfo, vrrpDesc map[string]*prometheus.Desc, legacyStateMetric bool) {
 	vrrpLabels := []string{proto, strconv.FormatUint(uint64(vrid), 10), iface, instance.Subinterface}
 
-	for _, state := range vrrpStates {
-		stateLabels := append(vrrpLabels, state)
+	if legacyStateMetric {
+		for _, state := range vrrpStates {
+			stateLabels := append(append([]string{}, vrrpLabels...), state)
 
-		var value float64
+			var value float64
+			if strings.EqualFold(instance.Status, state) {
+				value = 1
+			}
 
-		if strings.EqualFold(instance.Status, state) {
-			value = 1
+			newGauge(ch, vrrpDesc["vrrpState"], value, stateLabels...)
 		}
+	} else {
+		newGauge(ch, vrrpDesc["vrrpStateCode"], float64(vrrpStateCode(instance.Status)), vrrpLabels...)
 
-		newGauge(ch, vrrpDesc["vrrpState"], value, stateLabels...)
+		for _, state := range vrrpStates {
+			stateLabels := append(append([]string{}, vrrpLabels...), state)
+
+			var value float64
+			if strings.EqualFold(instance.Status, state) {
+				value = 1
+			}
+
+			newGauge(ch, vrrpDesc["vrrpStateInfo"], value, stateLabels...)
+		}
 	}
 
 	if instance.Statistics.AdverTx != nil {
@@ -130,4 +406,53 @@ func processInstance(ch chan<- prometheus.Metric, proto string, vrid uint32, ifa
 	if instance.Statistics.Transitions != nil {
 		newCounter(ch, vrrpDesc["transitions"], float64(*instance.Statistics.Transitions), vrrpLabels...)
 	}
+
+	if instance.Priority != nil {
+		newGauge(ch, vrrpDesc["priority"], float64(*instance.Priority), vrrpLabels...)
+	}
+
+	if instance.EffectivePriority != nil {
+		newGauge(ch, vrrpDesc["effectivePriority"], float64(*instance.EffectivePriority), vrrpLabels...)
+	}
+
+	if instance.AdvertisementInterval != nil {
+		newGauge(ch, vrrpDesc["advertisementInterval"], vrrpCentisecondsToSeconds(*instance.AdvertisementInterval), vrrpLabels...)
+	}
+
+	if instance.MasterAdverInterval != nil {
+		newGauge(ch, vrrpDesc["masterAdverInterval"], vrrpCentisecondsToSeconds(*instance.MasterAdverInterval), vrrpLabels...)
+	}
+
+	if instance.SkewTime != nil {
+		newGauge(ch, vrrpDesc["skewTime"], vrrpCentisecondsToSeconds(*instance.SkewTime), vrrpLabels...)
+	}
+
+	if instance.MasterDownInterval != nil {
+		newGauge(ch, vrrpDesc["masterDownInterval"], vrrpCentisecondsToSeconds(*instance.MasterDownInterval), vrrpLabels...)
+	}
+
+	if instance.Preempt != nil {
+		newGauge(ch, vrrpDesc["preempt"], boolToFloat64(*instance.Preempt), vrrpLabels...)
+	}
+
+	if instance.AcceptMode != nil {
+		newGauge(ch, vrrpDesc["acceptMode"], boolToFloat64(*instance.AcceptMode), vrrpLabels...)
+	}
+
+	if instance.AddressOwner != nil {
+		newGauge(ch, vrrpDesc["addressOwner"], boolToFloat64(*instance.AddressOwner), vrrpLabels...)
+	}
+
+	if instance.Mac != "" || len(instance.Addresses) > 0 {
+		infoLabels := append(append([]string{}, vrrpLabels...), instance.Mac, strings.Join(instance.Addresses, ","))
+		newGauge(ch, vrrpDesc["instanceInfo"], 1, infoLabels...)
+	}
+}
+
+// boolToFloat64 converts a bool to its Prometheus float64 representation (1 for true, 0 for false).
+func boolToFloat64(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
 }
@@ -0,0 +1,160 @@
+package collector
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestProcessVRRPInfoFiltering(t *testing.T) {
+	c := &vrrpCollector{
+		descriptions:      getVRRPDesc(),
+		legacyStateMetric: true,
+		vridFilters:       []string{"10", "20"},
+		interfaceFilters:  []string{"*"},
+		addressFamily:     "both",
+		advertTrackers:    make(map[string]*vrrpAdvertTracker),
+	}
+
+	ch := make(chan prometheus.Metric, 1024)
+	if err := c.processVRRPInfo(ch, readTestFixture(t, "show_vrrp_multi_vrid.json")); err != nil {
+		t.Errorf("error calling processVRRPInfo: %s", err)
+	}
+	close(ch)
+
+	gotVrids := make(map[string]bool)
+	for {
+		msg, more := <-ch
+		if !more {
+			break
+		}
+
+		metric := &dto.Metric{}
+		if err := msg.Write(metric); err != nil {
+			t.Errorf("error writing metric: %s", err)
+			continue
+		}
+
+		for _, label := range metric.GetLabel() {
+			if label.GetName() == "vrid" {
+				gotVrids[label.GetValue()] = true
+			}
+		}
+	}
+
+	for _, want := range []string{"10", "20"} {
+		if !gotVrids[want] {
+			t.Errorf("expected metrics for allowed vrid %s, got none", want)
+		}
+	}
+
+	if gotVrids["30"] {
+		t.Errorf("vrid 30 should have been filtered out by --collector.vrrp.vrids")
+	}
+}
+
+func TestProcessVRRPInfoAddressFamilyFilter(t *testing.T) {
+	c := &vrrpCollector{
+		descriptions:      getVRRPDesc(),
+		legacyStateMetric: true,
+		vridFilters:       []string{"*"},
+		interfaceFilters:  []string{"*"},
+		addressFamily:     "v4",
+		advertTrackers:    make(map[string]*vrrpAdvertTracker),
+	}
+
+	ch := make(chan prometheus.Metric, 1024)
+	if err := c.processVRRPInfo(ch, readTestFixture(t, "show_vrrp_multi_vrid.json")); err != nil {
+		t.Errorf("error calling processVRRPInfo: %s", err)
+	}
+	close(ch)
+
+	gotProtos := make(map[string]bool)
+	for {
+		msg, more := <-ch
+		if !more {
+			break
+		}
+
+		metric := &dto.Metric{}
+		if err := msg.Write(metric); err != nil {
+			t.Errorf("error writing metric: %s", err)
+			continue
+		}
+
+		for _, label := range metric.GetLabel() {
+			if label.GetName() == "proto" {
+				gotProtos[label.GetValue()] = true
+			}
+		}
+	}
+
+	if !gotProtos["v4"] {
+		t.Errorf("expected v4 metrics to be emitted")
+	}
+
+	if gotProtos["v6"] {
+		t.Errorf("v6 metrics should have been filtered out by --collector.vrrp.address-family=v4")
+	}
+}
+
+// TestProcessVRRPInfoSingleVRID verifies that the single-VRID response from
+// `show vrrp vrid <id> json` is handled, since FRR wraps it in a JSON array
+// just like the all-VRID `show vrrp json` response, and that the timer
+// fields are converted from centiseconds to seconds.
+func TestProcessVRRPInfoSingleVRID(t *testing.T) {
+	c := &vrrpCollector{
+		descriptions:      getVRRPDesc(),
+		legacyStateMetric: true,
+		vridFilters:       []string{"10"},
+		interfaceFilters:  []string{"*"},
+		addressFamily:     "v4",
+		advertTrackers:    make(map[string]*vrrpAdvertTracker),
+	}
+
+	ch := make(chan prometheus.Metric, 1024)
+	if err := c.processVRRPInfo(ch, readTestFixture(t, "show_vrrp_vrid_single.json")); err != nil {
+		t.Errorf("error calling processVRRPInfo: %s", err)
+	}
+	close(ch)
+
+	gotGauges := make(map[string]float64)
+	for {
+		msg, more := <-ch
+		if !more {
+			break
+		}
+
+		metric := &dto.Metric{}
+		if err := msg.Write(metric); err != nil {
+			t.Errorf("error writing metric: %s", err)
+			continue
+		}
+
+		if metric.GetGauge() == nil {
+			continue
+		}
+
+		re := regexp.MustCompile(`fqName: "([^"]+)"`)
+		match := re.FindStringSubmatch(msg.Desc().String())
+		if match == nil {
+			continue
+		}
+
+		gotGauges[match[1]] = metric.GetGauge().GetValue()
+	}
+
+	want := map[string]float64{
+		"frr_vrrp_advertisement_interval_seconds": 1,
+		"frr_vrrp_skew_time_seconds":              0.1,
+	}
+
+	for name, wantVal := range want {
+		gotVal, ok := gotGauges[name]
+		if !ok || gotVal != wantVal {
+			t.Errorf("metric %s: got %v (present=%v), want %v", name, gotVal, ok, wantVal)
+		}
+	}
+}